@@ -2,6 +2,7 @@ package check
 
 import (
 	"bytes"
+	"crypto/rsa"
 	_ "crypto/sha512" // for tls cipher support
 	"encoding/hex"
 	"encoding/json"
@@ -32,6 +33,15 @@ var (
 
 var NoUpdateAvailable error = fmt.Errorf("No update available")
 
+// HTTPDoer is satisfied by *http.Client, and by anything else that can
+// execute a request the way one does. Params.Doer accepts an HTTPDoer so
+// callers can route update checks through a custom transport (mTLS,
+// corporate proxy, request logging, ...) without CheckForUpdate knowing
+// about it.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 type Params struct {
 	// protocol version
 	Version int `json:"version"`
@@ -51,6 +61,32 @@ type Params struct {
 	Channel string `json:"-"`
 	// tags for custom update channels
 	Tags map[string]string `json:"tags"`
+
+	// publicKey, if set via SetPublicKey/SetPublicKeyPEM, overrides the
+	// public key baked into the update.Update passed to CheckForUpdate
+	// for the purposes of validating the server's response signature.
+	// update.Update only supports RSA signature verification.
+	publicKey *rsa.PublicKey
+
+	// Doer executes the update check request. If nil, update.HTTPClient
+	// is used, preserving the previous default behavior.
+	Doer HTTPDoer `json:"-"`
+	// RequestSigner, if set, is called with the outgoing request after
+	// its body and nonce header are set but before it is sent, so
+	// callers can attach auth headers, HMAC signatures, a custom
+	// User-Agent, etc.
+	RequestSigner func(*http.Request) error `json:"-"`
+
+	// AcceptPatchTypes lists the delta patch formats, in order of
+	// preference, that this client knows how to apply. It is sent as an
+	// Accept-Patch header; if empty, the server is assumed to only know
+	// about the original bsdiff format.
+	AcceptPatchTypes []update.PatchType `json:"-"`
+
+	// DownloadRetry configures retry/resume behavior for the download
+	// performed by Result.Update. The zero value makes up to 3 attempts
+	// with a 1 second initial backoff (see DownloadRetry).
+	DownloadRetry DownloadRetry `json:"-"`
 }
 
 type Result struct {
@@ -70,6 +106,28 @@ type Result struct {
 	Checksum string `json:"checksum"`
 	// signature for verifying update authenticity
 	Signature string `json:"signature"`
+	// Rollout, if non-nil, gates Update() to only a bucket of clients,
+	// see ErrNotInRollout.
+	Rollout *Rollout `json:"rollout"`
+
+	// Progress, if set before calling Update, is called periodically
+	// during the download with the bytes downloaded so far and the
+	// total size (total is 0 if the server did not report a
+	// Content-Length).
+	Progress func(downloaded, total int64)
+
+	// userId is the Params.UserId that produced this Result, used to
+	// bucket the client for Rollout.
+	userId string
+	// downloadRetry is copied from the Params.DownloadRetry that
+	// produced this Result.
+	downloadRetry DownloadRetry
+	// doer and requestSigner are copied from the Params.Doer and
+	// Params.RequestSigner that produced this Result, so the binary
+	// download goes through the same transport/auth as the check
+	// itself.
+	doer          HTTPDoer
+	requestSigner func(*http.Request) error
 }
 
 var rand *mathrand.Rand
@@ -136,13 +194,28 @@ func (p *Params) CheckForUpdate(url string, up *update.Update) (*Result, error)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	if len(p.AcceptPatchTypes) > 0 {
+		req.Header.Set("Accept-Patch", joinPatchTypes(p.AcceptPatchTypes))
+	}
+
 	nonce := rand.Int63()
 	// This nonce is a random number that is going to alter the server's message
 	// signature, which is sent by the server as a header and verified by the
 	// client.
 	req.Header.Set("X-Message-Nonce", fmt.Sprintf("%d", nonce))
 
-	resp, err := update.HTTPClient.Do(req)
+	if p.RequestSigner != nil {
+		if err := p.RequestSigner(req); err != nil {
+			return nil, fmt.Errorf("Error signing update request: %v", err)
+		}
+	}
+
+	doer := p.Doer
+	if doer == nil {
+		doer = update.HTTPClient
+	}
+
+	resp, err := doer.Do(req)
 	if err != nil {
 		log.Errorf("Error submitting update request: %v", err)
 		return nil, err
@@ -166,24 +239,35 @@ func (p *Params) CheckForUpdate(url string, up *update.Update) (*Result, error)
 		return nil, err
 	}
 
-	// Checking signature
+	if p.publicKey != nil {
+		up.PublicKey = p.publicKey
+	}
+
+	// Checking signature. update.Update.ValidateMessage only verifies RSA
+	// signatures, so there is nothing to negotiate here: any
+	// X-Message-Signature-Algorithm the server might send is intentionally
+	// not read, and a server signing with a different algorithm will just
+	// fail verification below.
 	if err := up.ValidateMessage(respBytes, signature, nonce); err != nil {
 		return nil, fmt.Errorf("Failed to validate message: %v", err)
 	}
 
 	// Working with the result
-	result := &Result{up: up}
+	result := &Result{up: up, userId: p.UserId, downloadRetry: p.DownloadRetry, doer: doer, requestSigner: p.RequestSigner}
 	if err := json.Unmarshal(respBytes, result); err != nil {
 		log.Errorf("Error reading JSON response body from update server: %v", err)
 		return nil, err
 	}
 
 	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
-		result := &Result{up: up}
+		result := &Result{up: up, userId: p.UserId, downloadRetry: p.DownloadRetry, doer: doer, requestSigner: p.RequestSigner}
 		if err := json.Unmarshal(respBytes, result); err != nil {
 			log.Errorf("Error reading JSON response body from update server: %v", err)
 			return nil, fmt.Errorf("json.Unmarshal: %v (text was %q)", err, string(respBytes))
 		}
+		if patchType := resp.Header.Get("X-Patch-Type"); patchType != "" && result.PatchType == "" {
+			result.PatchType = update.PatchType(patchType)
+		}
 		return result, nil
 	}
 
@@ -204,6 +288,11 @@ func (p *Params) CheckAndApplyUpdate(url string, up *update.Update) (result *Res
 }
 
 func (r *Result) Update() (err error, errRecover error) {
+	if r.Rollout != nil && !r.Rollout.includes(r.userId) {
+		err = ErrNotInRollout
+		return
+	}
+
 	if r.Checksum != "" {
 		r.up.Checksum, err = hex.DecodeString(r.Checksum)
 		if err != nil {
@@ -228,7 +317,7 @@ func (r *Result) Update() (err error, errRecover error) {
 	}
 
 	if r.PatchUrl != "" {
-		err, errRecover = r.up.FromUrl(r.PatchUrl)
+		err, errRecover = r.fromUrl(r.PatchUrl)
 		if err == nil {
 			// success!
 			return
@@ -244,7 +333,7 @@ func (r *Result) Update() (err error, errRecover error) {
 
 	// try updating from a URL with the full contents
 	r.up.PatchType = update.PATCHTYPE_NONE
-	return r.up.FromUrl(r.Url)
+	return r.fromUrl(r.Url)
 }
 
 func defaultChecksum() string {
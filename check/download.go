@@ -0,0 +1,176 @@
+package check
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/getlantern/go-update"
+)
+
+// DownloadRetry configures how Result.Update retries a download that is
+// interrupted partway through, resuming it with a Range request rather
+// than starting over.
+type DownloadRetry struct {
+	// MaxAttempts is the maximum number of attempts, including the
+	// first. Defaults to 3 if zero.
+	MaxAttempts int
+	// BackoffBase is the delay before the first retry; the Nth retry
+	// (attempt N+1) waits BackoffBase * 2^(N-1). Defaults to 1 second if
+	// zero.
+	BackoffBase time.Duration
+}
+
+const defaultMaxDownloadAttempts = 3
+const defaultDownloadBackoffBase = 1 * time.Second
+
+// fromUrl downloads url to a temp file, resuming with a Range request on
+// retry, and applies it via up.FromStream. It replaces a direct
+// up.FromUrl call so that transient network failures on large downloads
+// don't force starting over from byte zero.
+func (r *Result) fromUrl(url string) (err error, errRecover error) {
+	file, err := r.download(url)
+	if file != nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+	}
+	if err != nil {
+		return err, nil
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return err, nil
+	}
+
+	if r.up.PatchType == PatchTypeZstd {
+		decoded, decodeErr := decodeZstdPatch(file)
+		if decodeErr != nil {
+			return decodeErr, nil
+		}
+		defer decoded.Close()
+
+		r.up.PatchType = update.PATCHTYPE_NONE
+		return r.up.FromStream(decoded)
+	}
+
+	return r.up.FromStream(file)
+}
+
+func (r *Result) download(url string) (*os.File, error) {
+	file, err := ioutil.TempFile("", "go-update")
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := r.downloadRetry.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultMaxDownloadAttempts
+	}
+	backoff := r.downloadRetry.BackoffBase
+	if backoff <= 0 {
+		backoff = defaultDownloadBackoffBase
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(backoff * (1 << uint(attempt-2)))
+		}
+
+		if err := r.downloadAttempt(file, url); err != nil {
+			lastErr = err
+			log.Errorf("Download attempt %d/%d for %v failed: %v", attempt, attempts, url, err)
+			continue
+		}
+
+		return file, nil
+	}
+
+	file.Close()
+	os.Remove(file.Name())
+	return nil, fmt.Errorf("download failed after %d attempts: %v", attempts, lastErr)
+}
+
+// downloadAttempt resumes writing into file from its current size,
+// falling back to a full download if the server doesn't honor Range.
+func (r *Result) downloadAttempt(file *os.File, url string) error {
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	if r.requestSigner != nil {
+		if err := r.requestSigner(req); err != nil {
+			return fmt.Errorf("Error signing download request: %v", err)
+		}
+	}
+
+	doer := r.doer
+	if doer == nil {
+		doer = update.HTTPClient
+	}
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// server honored our Range request; keep what we have.
+	case http.StatusOK:
+		// server ignored Range and is sending the whole body; start over.
+		if offset > 0 {
+			if err := file.Truncate(0); err != nil {
+				return err
+			}
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			offset = 0
+		}
+	default:
+		return fmt.Errorf("unexpected status %d downloading %v", resp.StatusCode, url)
+	}
+
+	var total int64
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	if r.Progress != nil {
+		r.Progress(offset, total)
+	}
+
+	downloaded := offset
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, writeErr := file.Write(buf[:n]); writeErr != nil {
+				return writeErr
+			}
+			downloaded += int64(n)
+			if r.Progress != nil {
+				r.Progress(downloaded, total)
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
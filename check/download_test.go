@@ -0,0 +1,148 @@
+package check
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+type fakeDoer struct {
+	t         *testing.T
+	responses []*http.Response
+	requests  []*http.Request
+}
+
+func (f *fakeDoer) Do(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+	if len(f.responses) == 0 {
+		f.t.Fatalf("unexpected request %d: %v", len(f.requests), req)
+	}
+	resp := f.responses[0]
+	f.responses = f.responses[1:]
+	return resp, nil
+}
+
+func newResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+	}
+}
+
+func TestResultDownloadResumesWithRange(t *testing.T) {
+	doer := &fakeDoer{t: t}
+
+	var signed []*http.Request
+	r := &Result{
+		doer: doer,
+		requestSigner: func(req *http.Request) error {
+			signed = append(signed, req)
+			return nil
+		},
+	}
+
+	// The file already holds "hello, ", as if an earlier attempt had been
+	// cut off; this attempt should resume from those bytes with a Range
+	// request and append what the fake server sends.
+	doer.responses = []*http.Response{
+		newResponse(http.StatusPartialContent, "world"),
+	}
+
+	file, err := ioutil.TempFile("", "go-update-test")
+	if err != nil {
+		t.Fatalf("TempFile: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString("hello, "); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+
+	if err := r.downloadAttempt(file, "http://example.invalid/update"); err != nil {
+		t.Fatalf("downloadAttempt: %v", err)
+	}
+
+	if len(signed) != 1 {
+		t.Fatalf("expected requestSigner to be called once, got %d", len(signed))
+	}
+
+	if got := doer.requests[0].Header.Get("Range"); got != "bytes=7-" {
+		t.Fatalf("Range header = %q, want %q", got, "bytes=7-")
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	got, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "hello, world" {
+		t.Fatalf("file contents = %q, want %q", got, "hello, world")
+	}
+}
+
+func TestResultDownloadReportsZeroTotalWithoutContentLength(t *testing.T) {
+	resp := newResponse(http.StatusOK, "full replacement")
+	resp.ContentLength = -1 // unknown, as net/http reports for chunked/no-header responses
+	doer := &fakeDoer{t: t, responses: []*http.Response{resp}}
+
+	var gotTotals []int64
+	r := &Result{
+		doer: doer,
+		Progress: func(downloaded, total int64) {
+			gotTotals = append(gotTotals, total)
+		},
+	}
+
+	file, err := ioutil.TempFile("", "go-update-test")
+	if err != nil {
+		t.Fatalf("TempFile: %v", err)
+	}
+	defer file.Close()
+
+	if err := r.downloadAttempt(file, "http://example.invalid/update"); err != nil {
+		t.Fatalf("downloadAttempt: %v", err)
+	}
+
+	for _, total := range gotTotals {
+		if total != 0 {
+			t.Fatalf("Progress total = %d, want 0 when Content-Length is unknown", total)
+		}
+	}
+}
+
+func TestResultDownloadFallsBackToFullBodyWhenRangeIgnored(t *testing.T) {
+	doer := &fakeDoer{t: t}
+	doer.responses = []*http.Response{
+		newResponse(http.StatusOK, "full replacement"),
+	}
+
+	r := &Result{doer: doer}
+
+	file, err := ioutil.TempFile("", "go-update-test")
+	if err != nil {
+		t.Fatalf("TempFile: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString("stale partial data"); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+
+	if err := r.downloadAttempt(file, "http://example.invalid/update"); err != nil {
+		t.Fatalf("downloadAttempt: %v", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	got, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "full replacement" {
+		t.Fatalf("file contents = %q, want %q", got, "full replacement")
+	}
+}
@@ -0,0 +1,183 @@
+package check
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"github.com/getlantern/go-update"
+)
+
+// GithubParams configures an update check against the GitHub Releases API,
+// for projects that simply publish binaries as release assets instead of
+// running a custom signed update server.
+type GithubParams struct {
+	// Owner is the GitHub user or organization that owns Repo.
+	Owner string
+	// Repo is the GitHub repository name.
+	Repo string
+	// Channel selects which releases are considered. The empty string
+	// (or "stable") only considers non-prerelease releases; any other
+	// value, e.g. "unstable", also considers prereleases and picks the
+	// newest release of either kind.
+	Channel string
+	// AssetPattern is matched against each release asset's name to pick
+	// the binary for the running platform. The literals "{{.OS}}" and
+	// "{{.Arch}}" are substituted with runtime.GOOS/runtime.GOARCH
+	// before matching, e.g. "myapp_{{.OS}}_{{.Arch}}.tar.gz".
+	AssetPattern string
+}
+
+const githubAPIBase = "https://api.github.com"
+
+// githubChecksumsAssetNames are asset names checked, in order, for a
+// checksums file to source Result.Checksum from.
+var githubChecksumsAssetNames = []string{"SHA256SUMS", "checksums.txt", "SHA256SUMS.txt"}
+
+type githubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadUrl string `json:"browser_download_url"`
+}
+
+type githubRelease struct {
+	TagName    string        `json:"tag_name"`
+	Prerelease bool          `json:"prerelease"`
+	Assets     []githubAsset `json:"assets"`
+}
+
+// CheckForUpdate queries the GitHub Releases API for Owner/Repo and
+// returns a Result describing the newest release that matches Channel and
+// whose assets contain one matching AssetPattern. It returns
+// NoUpdateAvailable if no matching release or asset is found. If one of
+// the release's assets looks like a checksums file, it is downloaded and
+// parsed to populate Result.Checksum for the matched asset.
+func (g *GithubParams) CheckForUpdate(up *update.Update) (*Result, error) {
+	releases, err := g.listReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := g.expandAssetPattern()
+
+	for _, release := range releases {
+		if release.Prerelease && !g.allowsPrerelease() {
+			continue
+		}
+
+		asset := findMatchingAsset(release.Assets, pattern)
+		if asset == nil {
+			continue
+		}
+
+		result := &Result{
+			up:      up,
+			Url:     asset.BrowserDownloadUrl,
+			Version: release.TagName,
+		}
+
+		if checksum, err := g.checksumForAsset(release.Assets, asset.Name); err == nil {
+			result.Checksum = checksum
+		} else {
+			log.Debugf("No checksum available for %v: %v", asset.Name, err)
+		}
+
+		return result, nil
+	}
+
+	return nil, NoUpdateAvailable
+}
+
+func (g *GithubParams) allowsPrerelease() bool {
+	return g.Channel != "" && g.Channel != "stable"
+}
+
+func (g *GithubParams) expandAssetPattern() string {
+	pattern := g.AssetPattern
+	pattern = strings.Replace(pattern, "{{.OS}}", runtime.GOOS, -1)
+	pattern = strings.Replace(pattern, "{{.Arch}}", runtime.GOARCH, -1)
+	return pattern
+}
+
+func (g *GithubParams) listReleases() ([]githubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases", githubAPIBase, g.Owner, g.Repo)
+	resp, err := update.HTTPClient.Get(url)
+	if err != nil {
+		log.Errorf("Error fetching GitHub releases for %s/%s: %v", g.Owner, g.Repo, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub releases API returned status %d for %s/%s", resp.StatusCode, g.Owner, g.Repo)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []githubRelease
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %v (text was %q)", err, string(body))
+	}
+
+	return releases, nil
+}
+
+func (g *GithubParams) checksumForAsset(assets []githubAsset, assetName string) (string, error) {
+	var sums *githubAsset
+	for _, name := range githubChecksumsAssetNames {
+		if a := findAssetByName(assets, name); a != nil {
+			sums = a
+			break
+		}
+	}
+	if sums == nil {
+		return "", fmt.Errorf("no checksums asset found")
+	}
+
+	resp, err := update.HTTPClient.Get(sums.BrowserDownloadUrl)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		checksum, file := fields[0], strings.TrimPrefix(fields[1], "*")
+		if file == assetName {
+			return checksum, nil
+		}
+	}
+
+	return "", fmt.Errorf("checksum for %v not found in %v", assetName, sums.Name)
+}
+
+func findMatchingAsset(assets []githubAsset, pattern string) *githubAsset {
+	if pattern == "" {
+		return nil
+	}
+	for i := range assets {
+		if assets[i].Name == pattern || strings.Contains(assets[i].Name, pattern) {
+			return &assets[i]
+		}
+	}
+	return nil
+}
+
+func findAssetByName(assets []githubAsset, name string) *githubAsset {
+	for i := range assets {
+		if assets[i].Name == name {
+			return &assets[i]
+		}
+	}
+	return nil
+}
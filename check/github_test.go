@@ -0,0 +1,90 @@
+package check
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFindMatchingAsset(t *testing.T) {
+	assets := []githubAsset{
+		{Name: "myapp_linux_amd64.tar.gz"},
+		{Name: "myapp_darwin_amd64.tar.gz"},
+		{Name: "SHA256SUMS"},
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{"exact substring match", "linux_amd64", "myapp_linux_amd64.tar.gz"},
+		{"no match", "windows_amd64", ""},
+		{"empty pattern never matches", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findMatchingAsset(assets, tt.pattern)
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("findMatchingAsset() = %v, want nil", got.Name)
+				}
+				return
+			}
+			if got == nil || got.Name != tt.want {
+				t.Fatalf("findMatchingAsset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowsPrerelease(t *testing.T) {
+	tests := []struct {
+		channel string
+		want    bool
+	}{
+		{"", false},
+		{"stable", false},
+		{"unstable", true},
+		{"beta", true},
+	}
+
+	for _, tt := range tests {
+		g := &GithubParams{Channel: tt.channel}
+		if got := g.allowsPrerelease(); got != tt.want {
+			t.Errorf("allowsPrerelease() for channel %q = %v, want %v", tt.channel, got, tt.want)
+		}
+	}
+}
+
+func TestChecksumForAsset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("deadbeef  myapp_linux_amd64.tar.gz\n" +
+			"cafef00d  myapp_darwin_amd64.tar.gz\n"))
+	}))
+	defer server.Close()
+
+	g := &GithubParams{}
+	assets := []githubAsset{
+		{Name: "myapp_linux_amd64.tar.gz", BrowserDownloadUrl: "http://example.invalid/asset"},
+		{Name: "SHA256SUMS", BrowserDownloadUrl: server.URL},
+	}
+
+	checksum, err := g.checksumForAsset(assets, "myapp_linux_amd64.tar.gz")
+	if err != nil {
+		t.Fatalf("checksumForAsset: %v", err)
+	}
+	if checksum != "deadbeef" {
+		t.Fatalf("checksumForAsset() = %q, want %q", checksum, "deadbeef")
+	}
+}
+
+func TestChecksumForAssetNoChecksumsAsset(t *testing.T) {
+	g := &GithubParams{}
+	assets := []githubAsset{{Name: "myapp_linux_amd64.tar.gz"}}
+
+	if _, err := g.checksumForAsset(assets, "myapp_linux_amd64.tar.gz"); err == nil {
+		t.Fatal("expected error when no checksums asset is present")
+	}
+}
@@ -0,0 +1,56 @@
+package check
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/getlantern/go-update"
+	"github.com/klauspost/compress/zstd"
+)
+
+// PatchTypeZstd is an additional update.PatchType, beyond
+// update.PATCHTYPE_BSDIFF, that a client can advertise via
+// Params.AcceptPatchTypes. It treats the download as a zstd-compressed
+// full replacement rather than a binary diff; Result.Update decompresses
+// it before handing the result to update.Update, which otherwise has no
+// notion of this format.
+//
+// There is deliberately no xdelta3 patch type here yet: without a real
+// decoder to apply it, advertising support for one would make
+// Result.Update silently fall back to a full download every time a
+// server offered it.
+const PatchTypeZstd update.PatchType = "zstd"
+
+// supportedPatchTypes are the patch types this package can actually apply,
+// via update.FromStream (update.PATCHTYPE_BSDIFF/update.PATCHTYPE_NONE) or
+// decodeZstdPatch (PatchTypeZstd). joinPatchTypes only advertises types
+// from this set, so a caller can't accidentally tell a server it supports
+// a format (e.g. "xdelta3") that would just fail once negotiated.
+var supportedPatchTypes = map[update.PatchType]bool{
+	update.PATCHTYPE_BSDIFF: true,
+	PatchTypeZstd:           true,
+}
+
+func joinPatchTypes(types []update.PatchType) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		if !supportedPatchTypes[t] {
+			continue
+		}
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ", ")
+}
+
+// decodeZstdPatch wraps r with a zstd decompressor. The caller is
+// responsible for then treating the result as a full replacement (i.e.
+// passing update.PATCHTYPE_NONE to FromStream), since zstd here means
+// "compressed full binary", not a diff against the current one.
+func decodeZstdPatch(r io.Reader) (io.ReadCloser, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("zstd: %v", err)
+	}
+	return zr.IOReadCloser(), nil
+}
@@ -0,0 +1,64 @@
+package check
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getlantern/go-update"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestJoinPatchTypesDropsUnsupportedEntries(t *testing.T) {
+	got := joinPatchTypes([]update.PatchType{update.PATCHTYPE_BSDIFF, "xdelta3", PatchTypeZstd})
+	want := "bsdiff, zstd"
+	if got != want {
+		t.Fatalf("joinPatchTypes = %q, want %q", got, want)
+	}
+}
+
+func TestFromUrlDecodesZstdFullReplacement(t *testing.T) {
+	want := []byte("this is the new binary contents")
+
+	var compressed bytes.Buffer
+	zw, err := zstd.NewWriter(&compressed)
+	if err != nil {
+		t.Fatalf("zstd.NewWriter: %v", err)
+	}
+	if _, err := zw.Write(want); err != nil {
+		t.Fatalf("zw.Write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zw.Close: %v", err)
+	}
+
+	dir := t.TempDir()
+	targetPath := filepath.Join(dir, "myapp")
+	if err := ioutil.WriteFile(targetPath, []byte("old contents"), 0755); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	doer := &fakeDoer{t: t, responses: []*http.Response{
+		newResponse(http.StatusOK, compressed.String()),
+	}}
+
+	up := &update.Update{TargetPath: targetPath, PatchType: PatchTypeZstd}
+	r := &Result{up: up, doer: doer}
+
+	if err, errRecover := r.fromUrl("http://example.invalid/update.zst"); err != nil {
+		t.Fatalf("fromUrl: %v (recover err: %v)", err, errRecover)
+	}
+
+	got, err := ioutil.ReadFile(targetPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("target contents = %q, want %q", got, want)
+	}
+
+	os.Remove(targetPath + ".old")
+}
@@ -0,0 +1,168 @@
+package check
+
+import (
+	mathrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/getlantern/go-update"
+)
+
+// DefaultPollInterval is the interval a Poller uses when none is given to
+// NewPoller.
+const DefaultPollInterval = 30 * time.Minute
+
+// Poller periodically calls Params.CheckForUpdate on an interval, so a
+// long-running process can discover and surface new versions without the
+// caller having to manage its own ticker. Results (and errors other than
+// NoUpdateAvailable) are delivered on the channel returned by Start.
+type Poller struct {
+	// Params is used for every poll. Before each poll, run snapshots
+	// *Params under mu and calls Params.CheckForUpdate on the copy, so
+	// SetChannel/Pause/Resume/Stop are safe to call concurrently with a
+	// running Poller and never block for the duration of the network
+	// call the way holding mu across it would.
+	Params *Params
+	// Url is the update server endpoint passed to Params.CheckForUpdate.
+	Url string
+	// Up is the update.Update passed to Params.CheckForUpdate.
+	Up *update.Update
+	// Interval is the base time between polls. Defaults to
+	// DefaultPollInterval if zero.
+	Interval time.Duration
+	// Jitter adds up to +/- Jitter/2 of random skew to each interval, to
+	// avoid many clients hitting the update server at the same instant.
+	Jitter time.Duration
+	// OnAvailable, if set, is called with a successfully fetched Result
+	// before it is also sent on the Start channel. It is intended for
+	// GUIs that want to show an update banner without necessarily
+	// consuming the channel themselves.
+	OnAvailable func(*Result)
+
+	mu      sync.Mutex
+	paused  bool
+	started bool
+	stopped bool
+	stop    chan struct{}
+}
+
+// PollResult is delivered on the channel returned by Start for every poll
+// that finds an update or fails with an error other than
+// NoUpdateAvailable. Polls that find no update, and intervals skipped
+// while paused, deliver nothing.
+type PollResult struct {
+	Result *Result
+	Err    error
+}
+
+// Start begins polling in a background goroutine and returns the channel
+// on which PollResults are delivered. Start may only be called once per
+// Poller.
+func (poller *Poller) Start() <-chan PollResult {
+	poller.mu.Lock()
+	if poller.started {
+		poller.mu.Unlock()
+		panic("check: Poller already started")
+	}
+	poller.started = true
+	stop := make(chan struct{})
+	poller.stop = stop
+	poller.mu.Unlock()
+
+	results := make(chan PollResult, 1)
+	go poller.run(stop, results)
+	return results
+}
+
+// Stop permanently halts polling. A stopped Poller cannot be restarted.
+func (poller *Poller) Stop() {
+	poller.mu.Lock()
+	defer poller.mu.Unlock()
+	if poller.started && !poller.stopped {
+		poller.stopped = true
+		close(poller.stop)
+	}
+}
+
+// Pause suspends polling until Resume is called. It has no effect on a
+// poll already in flight.
+func (poller *Poller) Pause() {
+	poller.mu.Lock()
+	defer poller.mu.Unlock()
+	poller.paused = true
+}
+
+// Resume undoes a previous Pause.
+func (poller *Poller) Resume() {
+	poller.mu.Lock()
+	defer poller.mu.Unlock()
+	poller.paused = false
+}
+
+// SetChannel switches the release channel (e.g. between "stable" and
+// "unstable") used by subsequent polls, without requiring a restart.
+func (poller *Poller) SetChannel(channel string) {
+	poller.mu.Lock()
+	defer poller.mu.Unlock()
+	poller.Params.Channel = channel
+}
+
+func (poller *Poller) run(stop chan struct{}, results chan<- PollResult) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(poller.nextInterval()):
+		}
+
+		poller.mu.Lock()
+		paused := poller.paused
+		params := *poller.Params
+		poller.mu.Unlock()
+
+		var result *Result
+		var err error
+		if !paused {
+			result, err = params.CheckForUpdate(poller.Url, poller.Up)
+		}
+
+		if paused || err == NoUpdateAvailable {
+			continue
+		}
+		if err != nil {
+			log.Errorf("Error polling for update: %v", err)
+			select {
+			case results <- PollResult{Err: err}:
+			case <-stop:
+				return
+			}
+			continue
+		}
+
+		if poller.OnAvailable != nil {
+			poller.OnAvailable(result)
+		}
+
+		select {
+		case results <- PollResult{Result: result}:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (poller *Poller) nextInterval() time.Duration {
+	interval := poller.Interval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	if poller.Jitter <= 0 {
+		return interval
+	}
+	skew := time.Duration(mathrand.Int63n(int64(poller.Jitter))) - poller.Jitter/2
+	interval += skew
+	if interval < 0 {
+		interval = 0
+	}
+	return interval
+}
@@ -0,0 +1,40 @@
+package check
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getlantern/go-update"
+)
+
+// TestPollerStopSetChannelRace exercises Start/SetChannel/Stop
+// concurrently; run with -race to catch data races on poller.stop and
+// poller.Params.
+func TestPollerStopSetChannelRace(t *testing.T) {
+	poller := &Poller{
+		Params:   &Params{OS: "android"},
+		Up:       &update.Update{},
+		Interval: time.Millisecond,
+	}
+
+	results := poller.Start()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			poller.SetChannel("unstable")
+		}
+	}()
+
+	go func() {
+		for range results {
+		}
+	}()
+
+	wg.Wait()
+	poller.Stop()
+	poller.Stop() // must not panic on double Stop
+}
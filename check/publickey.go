@@ -0,0 +1,44 @@
+package check
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// SetPublicKeyPEM parses a PEM-encoded PKIX RSA public key and uses it,
+// instead of whatever key is baked into the update.Update passed to
+// CheckForUpdate, to validate the response signature. update.Update only
+// supports RSA signature verification, so keys of any other type are
+// rejected; there is no negotiation of a signature algorithm with the
+// server (e.g. an X-Message-Signature-Algorithm response header) and none
+// is planned until update.Update itself supports verifying more than one.
+func (p *Params) SetPublicKeyPEM(pemBytes []byte) error {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return fmt.Errorf("no PEM block found in public key")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse PKIX public key: %v", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported public key type %T: only RSA keys are supported", key)
+	}
+
+	return p.SetPublicKey(rsaKey)
+}
+
+// SetPublicKey uses key, instead of whatever key is baked into the
+// update.Update passed to CheckForUpdate, to validate the response
+// signature. It takes *rsa.PublicKey rather than the more general
+// crypto.PublicKey because update.Update.ValidateMessage only ever
+// verifies RSA signatures.
+func (p *Params) SetPublicKey(key *rsa.PublicKey) error {
+	p.publicKey = key
+	return nil
+}
@@ -0,0 +1,54 @@
+package check
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func pemEncodePublicKey(t *testing.T, pub interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestSetPublicKeyPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		pemData []byte
+		wantErr bool
+	}{
+		{"rsa key accepted", pemEncodePublicKey(t, &rsaKey.PublicKey), false},
+		{"ecdsa key rejected", pemEncodePublicKey(t, &ecdsaKey.PublicKey), true},
+		{"garbage rejected", []byte("not a pem"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Params{}
+			err := p.SetPublicKeyPEM(tt.pemData)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetPublicKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && p.publicKey == nil {
+				t.Fatalf("SetPublicKeyPEM() did not set publicKey")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package check
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+)
+
+// ErrNotInRollout is returned by Result.Update when the Result has a
+// Rollout and the current client's bucket falls outside the percentage
+// currently rolled out.
+var ErrNotInRollout error = fmt.Errorf("Not in rollout")
+
+// Rollout gates a Result to only a percentage of clients, so a server
+// operator can ship an update to a subset of the fleet before going to
+// 100%, entirely from a static check response.
+type Rollout struct {
+	// Percentage of clients that should receive the update, in
+	// [0, 100]. If StartTime/EndTime are also set, this is the
+	// percentage once the ramp reaches EndTime.
+	Percentage float64 `json:"percentage"`
+	// Seed is mixed into the client bucketing hash, so a server can
+	// reshuffle which clients are in an early rollout by changing it
+	// between releases.
+	Seed string `json:"seed"`
+	// StartTime and EndTime, if both set, make Percentage ramp linearly
+	// from 0 at StartTime to Percentage at EndTime. Before StartTime no
+	// clients are included; after EndTime all clients under Percentage
+	// are.
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// includes reports whether the client identified by userId (falling back
+// to the machine's hostname if empty) falls within r's currently
+// effective percentage.
+func (r *Rollout) includes(userId string) bool {
+	bucket := rolloutBucket(r.Seed, clientId(userId))
+	return float64(bucket) < r.effectivePercentage()*100
+}
+
+func (r *Rollout) effectivePercentage() float64 {
+	if r.StartTime.IsZero() || r.EndTime.IsZero() || !r.EndTime.After(r.StartTime) {
+		return r.Percentage
+	}
+
+	now := time.Now()
+	if now.Before(r.StartTime) {
+		return 0
+	}
+	if now.After(r.EndTime) {
+		return r.Percentage
+	}
+
+	elapsed := now.Sub(r.StartTime)
+	total := r.EndTime.Sub(r.StartTime)
+	return r.Percentage * float64(elapsed) / float64(total)
+}
+
+// rolloutBucket deterministically maps (seed, id) to [0, 10000).
+func rolloutBucket(seed, id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(seed))
+	h.Write([]byte{0})
+	h.Write([]byte(id))
+	return int(h.Sum32() % 10000)
+}
+
+// clientId returns userId if set, falling back to the machine's hostname
+// so rollout bucketing still works for anonymous clients.
+func clientId(userId string) string {
+	if userId != "" {
+		return userId
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		return hostname
+	}
+	return ""
+}
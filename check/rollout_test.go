@@ -0,0 +1,98 @@
+package check
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRolloutIncludesIsDeterministic(t *testing.T) {
+	r := &Rollout{Percentage: 50, Seed: "release-42"}
+
+	first := r.includes("user-1")
+	for i := 0; i < 5; i++ {
+		if got := r.includes("user-1"); got != first {
+			t.Fatalf("includes() is not deterministic: got %v, want %v", got, first)
+		}
+	}
+}
+
+func TestRolloutDifferentSeedsReshuffleBuckets(t *testing.T) {
+	a := &Rollout{Percentage: 50, Seed: "release-42"}
+	b := &Rollout{Percentage: 50, Seed: "release-43"}
+
+	differed := false
+	for i := 0; i < 50; i++ {
+		id := string(rune('a' + i))
+		if a.includes(id) != b.includes(id) {
+			differed = true
+			break
+		}
+	}
+	if !differed {
+		t.Fatal("expected different seeds to bucket at least one client differently")
+	}
+}
+
+func TestRolloutZeroAndFullPercentage(t *testing.T) {
+	zero := &Rollout{Percentage: 0, Seed: "s"}
+	if zero.includes("anyone") {
+		t.Fatal("0% rollout should include no one")
+	}
+
+	full := &Rollout{Percentage: 100, Seed: "s"}
+	if !full.includes("anyone") {
+		t.Fatal("100% rollout should include everyone")
+	}
+}
+
+func TestRolloutLinearRamp(t *testing.T) {
+	now := time.Now()
+	r := &Rollout{
+		Percentage: 100,
+		Seed:       "s",
+		StartTime:  now.Add(-1 * time.Hour),
+		EndTime:    now.Add(1 * time.Hour),
+	}
+
+	// Halfway through the ramp, effective percentage should be ~50.
+	got := r.effectivePercentage()
+	if got < 45 || got > 55 {
+		t.Fatalf("effectivePercentage() at ramp midpoint = %v, want ~50", got)
+	}
+
+	before := &Rollout{
+		Percentage: 100,
+		Seed:       "s",
+		StartTime:  now.Add(1 * time.Hour),
+		EndTime:    now.Add(2 * time.Hour),
+	}
+	if before.effectivePercentage() != 0 {
+		t.Fatalf("effectivePercentage() before StartTime = %v, want 0", before.effectivePercentage())
+	}
+
+	after := &Rollout{
+		Percentage: 100,
+		Seed:       "s",
+		StartTime:  now.Add(-2 * time.Hour),
+		EndTime:    now.Add(-1 * time.Hour),
+	}
+	if after.effectivePercentage() != 100 {
+		t.Fatalf("effectivePercentage() after EndTime = %v, want 100", after.effectivePercentage())
+	}
+}
+
+func TestResultUpdateReturnsErrNotInRollout(t *testing.T) {
+	r := &Result{
+		Url:     "http://example.invalid/update",
+		Rollout: &Rollout{Percentage: 0, Seed: "s"},
+		userId:  "someone",
+	}
+
+	err, errRecover := r.Update()
+	if err != ErrNotInRollout {
+		t.Fatalf("Update() error = %v, want ErrNotInRollout", err)
+	}
+	if errRecover != nil {
+		t.Fatalf("Update() errRecover = %v, want nil", errRecover)
+	}
+}
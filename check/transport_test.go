@@ -0,0 +1,84 @@
+package check
+
+import (
+	"crypto"
+	cryptorand "crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getlantern/go-update"
+)
+
+type countingDoer struct {
+	HTTPDoer
+	calls int
+}
+
+func (c *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	c.calls++
+	return c.HTTPDoer.Do(req)
+}
+
+func TestCheckForUpdateUsesDoerAndRequestSigner(t *testing.T) {
+	priv, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+
+	var gotAuth, gotAcceptPatch string
+	body := []byte(`{"url":"http://example.invalid/app","version":"2.0","patch_type":""}`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotAcceptPatch = r.Header.Get("Accept-Patch")
+
+		nonce := r.Header.Get("X-Message-Nonce")
+		message := append(append([]byte{}, body...), []byte(nonce)...)
+		sum := sha256.Sum256(message)
+		sig, err := rsa.SignPKCS1v15(cryptorand.Reader, priv, crypto.SHA256, sum[:])
+		if err != nil {
+			t.Fatalf("SignPKCS1v15: %v", err)
+		}
+
+		w.Header().Set("X-Message-Signature", hex.EncodeToString(sig))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	doer := &countingDoer{HTTPDoer: server.Client()}
+	p := &Params{
+		OS:               "android", // skip computing a checksum from a real executable
+		Doer:             doer,
+		AcceptPatchTypes: []update.PatchType{update.PATCHTYPE_BSDIFF, PatchTypeZstd},
+		RequestSigner: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer test-token")
+			return nil
+		},
+	}
+	if err := p.SetPublicKey(&priv.PublicKey); err != nil {
+		t.Fatalf("SetPublicKey: %v", err)
+	}
+
+	up := &update.Update{}
+	result, err := p.CheckForUpdate(server.URL, up)
+	if err != nil {
+		t.Fatalf("CheckForUpdate: %v", err)
+	}
+
+	if result.Url != "http://example.invalid/app" {
+		t.Fatalf("result.Url = %q, want %q", result.Url, "http://example.invalid/app")
+	}
+	if doer.calls != 1 {
+		t.Fatalf("Doer called %d times, want 1", doer.calls)
+	}
+	if gotAuth != "Bearer test-token" {
+		t.Fatalf("Authorization header = %q, want signed-in value", gotAuth)
+	}
+	if gotAcceptPatch != "bsdiff, zstd" {
+		t.Fatalf("Accept-Patch header = %q, want %q", gotAcceptPatch, "bsdiff, zstd")
+	}
+}